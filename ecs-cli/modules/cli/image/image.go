@@ -0,0 +1,68 @@
+// Copyright 2015-2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package image provides the --scan flag support for the `ecs-cli images` command.
+package image
+
+import (
+	"fmt"
+
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients/aws/ecr"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// ScanFlag is the --scan flag: shows vulnerability scan findings alongside
+// image details.
+const ScanFlag = "scan"
+
+// severityPrintOrder lists severities from most to least severe, for
+// PrintScanFindings' summary output.
+var severityPrintOrder = []string{"CRITICAL", "HIGH", "MEDIUM", "LOW", "INFORMATIONAL", "UNDEFINED"}
+
+// Flags returns the images command's vulnerability scanning flag.
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		cli.BoolFlag{
+			Name:  ScanFlag,
+			Usage: "Shows vulnerability scan findings alongside image details.",
+		},
+	}
+}
+
+// PrintScanFindings prints repositoryName:imageTag's vulnerability scan findings: a severity
+// count breakdown followed by individual findings, or a notice if a scan was just started.
+func PrintScanFindings(client ecr.Client, repositoryName, imageTag string) error {
+	findings, err := client.GetImageScanFindings(repositoryName, imageTag)
+	if err != nil {
+		if err == ecr.ErrImageScanInProgress {
+			fmt.Println("Image scan in progress, check back shortly")
+			return nil
+		}
+		return errors.Wrap(err, "unable to get image scan findings")
+	}
+
+	fmt.Printf("Scan status: %s\n", findings.Status)
+	for _, severity := range severityPrintOrder {
+		if count := findings.SeverityCounts[severity]; count > 0 {
+			fmt.Printf("  %-14s %d\n", severity, count)
+		}
+	}
+
+	for _, finding := range findings.Findings {
+		fmt.Printf("  %s: %s\n", aws.StringValue(finding.Name), aws.StringValue(finding.Description))
+	}
+
+	return nil
+}
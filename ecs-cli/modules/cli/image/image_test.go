@@ -0,0 +1,51 @@
+// Copyright 2015-2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package image
+
+import (
+	"testing"
+
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients/aws/ecr"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClient is a hand-written ecr.Client test double covering only
+// GetImageScanFindings, the one method PrintScanFindings calls.
+type fakeClient struct {
+	ecr.Client
+	findings    *ecr.ScanFindings
+	findingsErr error
+}
+
+func (f *fakeClient) GetImageScanFindings(repositoryName, imageTagOrDigest string) (*ecr.ScanFindings, error) {
+	if f.findingsErr != nil {
+		return nil, f.findingsErr
+	}
+	return f.findings, nil
+}
+
+func TestPrintScanFindingsInProgress(t *testing.T) {
+	client := &fakeClient{findingsErr: ecr.ErrImageScanInProgress}
+	assert.NoError(t, PrintScanFindings(client, "myrepo", "latest"))
+}
+
+func TestPrintScanFindings(t *testing.T) {
+	client := &fakeClient{
+		findings: &ecr.ScanFindings{
+			Status:         "COMPLETE",
+			SeverityCounts: map[string]int64{"HIGH": 1},
+		},
+	}
+	assert.NoError(t, PrintScanFindings(client, "myrepo", "latest"))
+}
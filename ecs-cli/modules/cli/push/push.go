@@ -0,0 +1,155 @@
+// Copyright 2015-2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package push provides the flag parsing and repository-provisioning helpers for `ecs-cli push`.
+package push
+
+import (
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients/aws/ecr"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/config"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+const (
+	// LifecyclePolicyFlag is the --lifecycle-policy flag: a lifecycle
+	// policy JSON document, or a path to a file containing one, applied to
+	// a repository this command creates.
+	LifecyclePolicyFlag = "lifecycle-policy"
+	// ImageTagMutabilityFlag is the --image-tag-mutability flag: either
+	// "MUTABLE" or "IMMUTABLE".
+	ImageTagMutabilityFlag = "image-tag-mutability"
+	// ScanOnPushFlag is the --scan-on-push flag: enables vulnerability
+	// scanning on push for a repository this command creates.
+	ScanOnPushFlag = "scan-on-push"
+	// AssumeRoleArnFlag is the --assume-role-arn flag: pushes to a registry
+	// in another account using the credentials of this role.
+	AssumeRoleArnFlag = "assume-role-arn"
+	// ExternalIDFlag is the --external-id flag: an optional external ID to
+	// pass to sts:AssumeRole alongside --assume-role-arn.
+	ExternalIDFlag = "external-id"
+	// FailOnFlag is the --fail-on flag: after pushing, fails the command if
+	// the image has vulnerability scan findings at or above this severity
+	// (UNDEFINED, INFORMATIONAL, LOW, MEDIUM, HIGH, or CRITICAL).
+	FailOnFlag = "fail-on"
+)
+
+// Flags returns the push command's repository-provisioning flags.
+func Flags() []cli.Flag {
+	return []cli.Flag{
+		cli.StringFlag{
+			Name:  LifecyclePolicyFlag,
+			Usage: "A lifecycle policy JSON document, or a path to a file containing one, applied to a repository this command creates.",
+		},
+		cli.StringFlag{
+			Name:  ImageTagMutabilityFlag,
+			Usage: "Either MUTABLE or IMMUTABLE; configures tag mutability on a repository this command creates.",
+		},
+		cli.BoolFlag{
+			Name:  ScanOnPushFlag,
+			Usage: "Enables vulnerability scanning on push for a repository this command creates.",
+		},
+		cli.StringFlag{
+			Name:  AssumeRoleArnFlag,
+			Usage: "Pushes to a registry in another account using the credentials of this role.",
+		},
+		cli.StringFlag{
+			Name:  ExternalIDFlag,
+			Usage: "An optional external ID to pass to sts:AssumeRole alongside --assume-role-arn.",
+		},
+		cli.StringFlag{
+			Name:  FailOnFlag,
+			Usage: "After pushing, fails the command if the image has vulnerability scan findings at or above this severity.",
+		},
+	}
+}
+
+// ClientFromContext returns the ecr.Client the push command should use for repositoryURI, assuming
+// --assume-role-arn when set (public ECR has no assume-role client, so that combination errors).
+func ClientFromContext(cliConfig *config.CommandConfig, context *cli.Context, repositoryURI string) (ecr.Client, error) {
+	if assumeRoleArn := context.String(AssumeRoleArnFlag); assumeRoleArn != "" {
+		if ecr.IsPublicRegistryURI(repositoryURI) {
+			return nil, errors.New("--assume-role-arn is not supported for public ECR repositories")
+		}
+		return ecr.NewClientWithAssumedRole(cliConfig, assumeRoleArn, context.String(ExternalIDFlag)), nil
+	}
+	return ecr.NewClientForRegistry(cliConfig, repositoryURI), nil
+}
+
+// RepositoryOptionsFromContext builds the ecr.RepositoryOptions to apply when the push command creates a repository.
+func RepositoryOptionsFromContext(context *cli.Context) ecr.RepositoryOptions {
+	return ecr.RepositoryOptions{
+		LifecyclePolicy:    context.String(LifecyclePolicyFlag),
+		ImageTagMutability: context.String(ImageTagMutabilityFlag),
+		ScanOnPush:         context.Bool(ScanOnPushFlag),
+	}
+}
+
+// EnsureRepository creates repositoryURI's repository if it does not already exist and returns
+// the ecr.Client used, so callers can reuse it for authorization and the --fail-on scan gate.
+func EnsureRepository(cliConfig *config.CommandConfig, context *cli.Context, repositoryURI string) (ecr.Client, error) {
+	client, err := ClientFromContext(cliConfig, context, repositoryURI)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureRepositoryExists(client, context, repositoryURI); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// ensureRepositoryExists creates repositoryURI's repository via client if it does not already exist.
+func ensureRepositoryExists(client ecr.Client, context *cli.Context, repositoryURI string) error {
+	if client.RepositoryExists(repositoryURI) {
+		return nil
+	}
+
+	name, err := client.CreateRepository(repositoryURI, RepositoryOptionsFromContext(context))
+	if err != nil {
+		return errors.Wrap(err, "unable to create repository")
+	}
+
+	log.WithFields(log.Fields{
+		"repository": name,
+	}).Info("Created repository for push")
+
+	return nil
+}
+
+// CheckFailOnThreshold enforces the --fail-on gate, returning an error if repositoryURI:imageTag
+// has vulnerability findings at or above the requested severity. A no-op if --fail-on was not given.
+func CheckFailOnThreshold(client ecr.Client, context *cli.Context, repositoryURI, imageTag string) error {
+	threshold := context.String(FailOnFlag)
+	if threshold == "" {
+		return nil
+	}
+
+	findings, err := client.GetImageScanFindings(repositoryURI, imageTag)
+	if err != nil {
+		if err == ecr.ErrImageScanInProgress {
+			return errors.New("image scan still in progress; re-run to check --fail-on once it completes")
+		}
+		return errors.Wrap(err, "unable to get image scan findings")
+	}
+
+	exceeds, err := findings.ExceedsSeverityThreshold(threshold)
+	if err != nil {
+		return errors.Wrap(err, "invalid --fail-on threshold")
+	}
+	if exceeds {
+		return errors.Errorf("image %s:%s has vulnerability findings at or above %s", repositoryURI, imageTag, threshold)
+	}
+
+	return nil
+}
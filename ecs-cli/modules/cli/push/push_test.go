@@ -0,0 +1,148 @@
+// Copyright 2015-2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package push
+
+import (
+	"errors"
+	"flag"
+	"testing"
+
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients/aws/ecr"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+// fakeClient is a hand-written ecr.Client test double; the fields mirror
+// the subset of behavior EnsureRepository and CheckFailOnThreshold drive.
+type fakeClient struct {
+	ecr.Client
+	repositoryExists bool
+	createErr        error
+	createdName      string
+
+	gotCreateOptions ecr.RepositoryOptions
+
+	findings    *ecr.ScanFindings
+	findingsErr error
+}
+
+func (f *fakeClient) RepositoryExists(repositoryName string) bool {
+	return f.repositoryExists
+}
+
+func (f *fakeClient) CreateRepository(repositoryName string, options ecr.RepositoryOptions) (string, error) {
+	f.gotCreateOptions = options
+	if f.createErr != nil {
+		return "", f.createErr
+	}
+	return f.createdName, nil
+}
+
+func (f *fakeClient) GetImageScanFindings(repositoryName, imageTagOrDigest string) (*ecr.ScanFindings, error) {
+	if f.findingsErr != nil {
+		return nil, f.findingsErr
+	}
+	return f.findings, nil
+}
+
+func contextWithFlags(flags map[string]string) *cli.Context {
+	set := flag.NewFlagSet("test", 0)
+	for _, f := range Flags() {
+		f.Apply(set)
+	}
+	for name, value := range flags {
+		set.Set(name, value)
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestClientFromContextRejectsAssumeRoleForPublicECR(t *testing.T) {
+	context := contextWithFlags(map[string]string{
+		AssumeRoleArnFlag: "arn:aws:iam::123456789012:role/push",
+	})
+
+	client, err := ClientFromContext(nil, context, "public.ecr.aws/myalias/myrepo")
+	assert.Error(t, err)
+	assert.Nil(t, client)
+}
+
+func TestEnsureRepositoryExists(t *testing.T) {
+	t.Run("repository already exists, skips creation", func(t *testing.T) {
+		client := &fakeClient{repositoryExists: true, createErr: errors.New("should not be called")}
+		context := contextWithFlags(nil)
+
+		assert.NoError(t, ensureRepositoryExists(client, context, "myrepo"))
+	})
+
+	t.Run("repository missing, created with flags wired through", func(t *testing.T) {
+		client := &fakeClient{createdName: "myrepo"}
+		context := contextWithFlags(map[string]string{
+			LifecyclePolicyFlag:    `{"rules": []}`,
+			ImageTagMutabilityFlag: "IMMUTABLE",
+			ScanOnPushFlag:         "true",
+		})
+
+		assert.NoError(t, ensureRepositoryExists(client, context, "myrepo"))
+		assert.Equal(t, `{"rules": []}`, client.gotCreateOptions.LifecyclePolicy)
+		assert.Equal(t, "IMMUTABLE", client.gotCreateOptions.ImageTagMutability)
+		assert.True(t, client.gotCreateOptions.ScanOnPush)
+	})
+
+	t.Run("CreateRepository error propagates", func(t *testing.T) {
+		client := &fakeClient{createErr: errors.New("boom")}
+		context := contextWithFlags(nil)
+
+		err := ensureRepositoryExists(client, context, "myrepo")
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckFailOnThreshold(t *testing.T) {
+	cases := []struct {
+		name      string
+		threshold string
+		findings  *ecr.ScanFindings
+		wantErr   bool
+	}{
+		{
+			name:      "no --fail-on is a no-op",
+			threshold: "",
+		},
+		{
+			name:      "findings below threshold pass",
+			threshold: "HIGH",
+			findings:  &ecr.ScanFindings{SeverityCounts: map[string]int64{"LOW": 1}},
+		},
+		{
+			name:      "findings at or above threshold fail",
+			threshold: "HIGH",
+			findings:  &ecr.ScanFindings{SeverityCounts: map[string]int64{"CRITICAL": 1}},
+			wantErr:   true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			context := contextWithFlags(map[string]string{FailOnFlag: c.threshold})
+			client := &fakeClient{findings: c.findings}
+
+			err := CheckFailOnThreshold(client, context, "myrepo", "latest")
+			if c.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
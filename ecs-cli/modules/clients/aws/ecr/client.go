@@ -14,11 +14,16 @@
 package ecr
 
 import (
+	"io/ioutil"
+	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients"
 	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/config"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/endpoints"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/aws/aws-sdk-go/service/ecr/ecriface"
@@ -29,8 +34,56 @@ import (
 
 const (
 	CacheDir = "~/.ecs"
+
+	// publicRegistryHostname is the hostname used by the Amazon ECR Public
+	// Gallery, e.g. public.ecr.aws/<alias>/<repository>.
+	publicRegistryHostname = "public.ecr.aws"
 )
 
+// IsPublicRegistryURI returns true if registryURI's host is public.ecr.aws.
+func IsPublicRegistryURI(registryURI string) bool {
+	host := registryURI
+	if idx := strings.Index(host, "://"); idx != -1 {
+		host = host[idx+len("://"):]
+	}
+	if idx := strings.Index(host, "/"); idx != -1 {
+		host = host[:idx]
+	}
+	return host == publicRegistryHostname
+}
+
+// NewClientForRegistry returns a public or private ECR client depending on registryURI's hostname.
+func NewClientForRegistry(config *config.CommandConfig, registryURI string) Client {
+	if IsPublicRegistryURI(registryURI) {
+		return NewPublicClient(config)
+	}
+	return NewClient(config)
+}
+
+// repositoryURIPattern matches <accountID>.dkr.ecr.<region>.amazonaws.com/<repositoryName>.
+var repositoryURIPattern = regexp.MustCompile(`^(\d{12})\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?/(.+)$`)
+
+// registryURIPattern matches <accountID>.dkr.ecr.<region>.amazonaws.com, with no repository path.
+var registryURIPattern = regexp.MustCompile(`^(\d{12})\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// parseRepositoryURI extracts the registry ID, region, and repository name out of a repository URI.
+func parseRepositoryURI(uri string) (registryID, region, repositoryName string, ok bool) {
+	matches := repositoryURIPattern.FindStringSubmatch(uri)
+	if matches == nil {
+		return "", "", "", false
+	}
+	return matches[1], matches[2], matches[3], true
+}
+
+// parseRegistryURI extracts the registry ID and region out of a bare registry URI.
+func parseRegistryURI(registryURI string) (registryID, region string, ok bool) {
+	matches := registryURIPattern.FindStringSubmatch(registryURI)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], matches[2], true
+}
+
 // ProcessImageDetails callback function for describe images
 type ProcessImageDetails func(images []*ecr.ImageDetail) error
 
@@ -41,9 +94,16 @@ type ProcessRepositories func(repositories []*string) error
 type Client interface {
 	GetAuthorizationToken(registryURI string) (*Auth, error)
 	GetAuthorizationTokenByID(registryID string) (*Auth, error)
-	CreateRepository(repositoryName string) (string, error)
+	// CreateRepository accepts a bare repository name or a full repository URI, creating the repository in the referenced region.
+	CreateRepository(repositoryName string, options RepositoryOptions) (string, error)
+	// RepositoryExists accepts either a bare repository name or a full repository URI, as with CreateRepository.
 	RepositoryExists(repositoryName string) bool
+	// GetImages accepts either a plain registry ID or a full private registry URI for registryID;
+	// the public ECR client passes registryID through unparsed.
 	GetImages(repositoryNames []*string, tagStatus string, registryID string, processFn ProcessImageDetails) error
+	// GetImageScanFindings returns the vulnerability scan findings for a single image. If the image has no scan yet,
+	// it starts one and returns ErrImageScanInProgress instead of findings.
+	GetImageScanFindings(repositoryName, imageTagOrDigest string) (*ScanFindings, error)
 }
 
 // ecrClient implements Client
@@ -52,6 +112,22 @@ type ecrClient struct {
 	loginClient login.Client
 	config      *config.CommandConfig
 	auth        *Auth
+
+	// regionalClients caches ECR clients keyed by region, so that
+	// repository/registry URIs referencing a region other than the CLI's
+	// configured region can be served without re-establishing a session.
+	regionalClients     map[string]ecriface.ECRAPI
+	regionalClientsLock sync.Mutex
+
+	// awsConfig is the base AWS config used to construct client, and is
+	// reused (with the region overridden) by clientForRegion so that
+	// regional clients inherit the same credentials, e.g. an assumed role.
+	awsConfig *aws.Config
+
+	// useFips indicates client was built by NewFipsClient, so that
+	// clientForRegion knows to re-resolve a FIPS endpoint for the new
+	// region instead of reusing the original region's endpoint.
+	useFips bool
 }
 
 // NewClient Creates a new ECR client
@@ -63,7 +139,30 @@ func NewClient(config *config.CommandConfig) Client {
 		Config:   config.Session.Config,
 		CacheDir: CacheDir,
 	})
-	return newClient(config, client, loginClient)
+	return newClient(config, client, loginClient, config.Session.Config, false)
+}
+
+// NewClientWithAssumedRole creates a new ECR client using credentials assumed from assumeRoleArn.
+func NewClientWithAssumedRole(config *config.CommandConfig, assumeRoleArn, externalID string) Client {
+	awsSession := config.Session
+
+	assumedCreds := stscreds.NewCredentials(awsSession, assumeRoleArn, func(p *stscreds.AssumeRoleProvider) {
+		if externalID != "" {
+			p.ExternalID = aws.String(externalID)
+		}
+	})
+
+	awsConfig := &aws.Config{Credentials: assumedCreds}
+
+	client := ecr.New(awsSession, awsConfig)
+	client.Handlers.Build.PushBackNamed(clients.CustomUserAgentHandler())
+	loginClient := login.DefaultClientFactory{}.NewClientWithOptions(login.Options{
+		Session:  awsSession,
+		Config:   awsConfig,
+		CacheDir: CacheDir,
+	})
+
+	return newClient(config, client, loginClient, awsConfig, false)
 }
 
 // NewFipsClient Creates a new ECR client that will communicate with a FIPS endpoint.
@@ -102,17 +201,64 @@ func NewFipsClient(config *config.CommandConfig) (Client, error) {
 		CacheDir: CacheDir,
 	})
 
-	return newClient(config, client, loginClient), nil
+	return newClient(config, client, loginClient, awsConfig, true), nil
 }
 
-func newClient(config *config.CommandConfig, client ecriface.ECRAPI, loginClient login.Client) Client {
+func newClient(config *config.CommandConfig, client ecriface.ECRAPI, loginClient login.Client, awsConfig *aws.Config, useFips bool) Client {
 	return &ecrClient{
-		config:      config,
-		client:      client,
-		loginClient: loginClient,
+		config:          config,
+		client:          client,
+		loginClient:     loginClient,
+		regionalClients: map[string]ecriface.ECRAPI{},
+		awsConfig:       awsConfig,
+		useFips:         useFips,
 	}
 }
 
+// clientForRegion returns the ECR client scoped to region, caching it if newly constructed.
+func (c *ecrClient) clientForRegion(region string) ecriface.ECRAPI {
+	if region == "" || region == aws.StringValue(c.config.Session.Config.Region) {
+		return c.client
+	}
+
+	c.regionalClientsLock.Lock()
+	defer c.regionalClientsLock.Unlock()
+
+	if client, ok := c.regionalClients[region]; ok {
+		return client
+	}
+
+	log.WithFields(log.Fields{
+		"region": region,
+	}).Debug("Creating regional ECR client")
+
+	regionalConfig := c.awsConfig.Copy(&aws.Config{Region: aws.String(region)})
+
+	// A FIPS client resolved its Endpoint for the CLI's original region;
+	// reusing that Endpoint here would sign requests for the new region
+	// but send them to the old region's FIPS host. Re-resolve instead.
+	if c.useFips {
+		resolver := endpoints.DefaultResolver()
+		endpoint, err := resolver.EndpointFor("ecr-fips", region, func(opts *endpoints.Options) {
+			opts.ResolveUnknownService = true
+		})
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"region": region,
+			}).Warn("Unable to resolve FIPS endpoint for region; falling back to standard endpoint")
+			regionalConfig.Endpoint = nil
+		} else {
+			regionalConfig.Endpoint = aws.String(endpoint.URL)
+		}
+	}
+
+	client := ecr.New(c.config.Session, regionalConfig)
+	client.Handlers.Build.PushBackNamed(clients.CustomUserAgentHandler())
+	c.regionalClients[region] = client
+
+	return client
+}
+
 // Auth keeps track of the ECR auth
 type Auth struct {
 	ProxyEndpoint string
@@ -121,6 +267,16 @@ type Auth struct {
 	Password      string
 }
 
+// RepositoryOptions configures optional settings applied when CreateRepository provisions a new repository.
+type RepositoryOptions struct {
+	// LifecyclePolicy is a lifecycle policy document or a path to one. Empty skips lifecycle policy configuration.
+	LifecyclePolicy string
+	// ImageTagMutability is either "MUTABLE" or "IMMUTABLE". Empty leaves the registry default in place.
+	ImageTagMutability string
+	// ScanOnPush enables vulnerability scanning on every image push.
+	ScanOnPush bool
+}
+
 func (c *ecrClient) GetAuthorizationTokenByID(registryID string) (*Auth, error) {
 	log.Debug("Getting authorization token...")
 
@@ -155,20 +311,43 @@ func (c *ecrClient) GetAuthorizationToken(registryURI string) (*Auth, error) {
 }
 
 func (c *ecrClient) RepositoryExists(repositoryName string) bool {
-	_, err := c.client.DescribeRepositories(&ecr.DescribeRepositoriesInput{RepositoryNames: []*string{&repositoryName}})
+	region := ""
+	if _, parsedRegion, parsedName, ok := parseRepositoryURI(repositoryName); ok {
+		region = parsedRegion
+		repositoryName = parsedName
+	}
+
+	_, err := c.clientForRegion(region).DescribeRepositories(&ecr.DescribeRepositoriesInput{RepositoryNames: []*string{&repositoryName}})
 	log.WithFields(log.Fields{
 		"repository": repositoryName,
+		"region":     region,
 	}).Debug("Check if repository exists")
 	return err == nil
 }
 
-func (c *ecrClient) CreateRepository(repositoryName string) (string, error) {
+func (c *ecrClient) CreateRepository(repositoryName string, options RepositoryOptions) (string, error) {
+	region := ""
+	if _, parsedRegion, parsedName, ok := parseRepositoryURI(repositoryName); ok {
+		region = parsedRegion
+		repositoryName = parsedName
+	}
+
 	log.WithFields(log.Fields{
 		"repository": repositoryName,
+		"region":     region,
 	}).Info("Creating repository")
 
-	resp, err := c.client.CreateRepository(
-		&ecr.CreateRepositoryInput{RepositoryName: aws.String(repositoryName)})
+	client := c.clientForRegion(region)
+
+	input := &ecr.CreateRepositoryInput{RepositoryName: aws.String(repositoryName)}
+	if options.ImageTagMutability != "" {
+		input.SetImageTagMutability(options.ImageTagMutability)
+	}
+	if options.ScanOnPush {
+		input.SetImageScanningConfiguration(&ecr.ImageScanningConfiguration{ScanOnPush: aws.Bool(true)})
+	}
+
+	resp, err := client.CreateRepository(input)
 	if err != nil {
 		return "", errors.Wrap(err, "unable to create repository")
 	}
@@ -176,16 +355,64 @@ func (c *ecrClient) CreateRepository(repositoryName string) (string, error) {
 		return "", errors.New("create repository response is empty")
 	}
 
+	if options.LifecyclePolicy != "" {
+		lifecyclePolicy, err := resolveLifecyclePolicy(options.LifecyclePolicy)
+		if err != nil {
+			return "", err
+		}
+		if err := c.putLifecyclePolicy(client, repositoryName, lifecyclePolicy); err != nil {
+			return "", err
+		}
+	}
+
 	log.Info("Repository created")
 	return aws.StringValue(resp.Repository.RepositoryName), nil
 }
 
+// resolveLifecyclePolicy returns policy itself if it is a JSON document, otherwise reads it as a file path.
+func resolveLifecyclePolicy(policy string) (string, error) {
+	if strings.HasPrefix(strings.TrimSpace(policy), "{") {
+		return policy, nil
+	}
+
+	contents, err := ioutil.ReadFile(policy)
+	if err != nil {
+		return "", errors.Wrap(err, "unable to read lifecycle policy file")
+	}
+	return string(contents), nil
+}
+
+// putLifecyclePolicy applies a lifecycle policy document to a newly created repository.
+func (c *ecrClient) putLifecyclePolicy(client ecriface.ECRAPI, repositoryName, lifecyclePolicy string) error {
+	log.WithFields(log.Fields{
+		"repository": repositoryName,
+	}).Info("Applying lifecycle policy to repository")
+
+	_, err := client.PutLifecyclePolicy(&ecr.PutLifecyclePolicyInput{
+		RepositoryName:      aws.String(repositoryName),
+		LifecyclePolicyText: aws.String(lifecyclePolicy),
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to apply lifecycle policy to repository")
+	}
+
+	return nil
+}
+
 func (c *ecrClient) GetImages(repositoryNames []*string, tagStatus string, registryID string, processFn ProcessImageDetails) error {
 	log.Debug("Getting images from ECR...")
+
+	region := ""
+	if parsedID, parsedRegion, ok := parseRegistryURI(registryID); ok {
+		region = parsedRegion
+		registryID = parsedID
+	}
+	client := c.clientForRegion(region)
+
 	pageNumber := 0
-	err := c.describeRepositories(repositoryNames, registryID, func(repositories []*string) error {
+	err := c.describeRepositories(client, repositoryNames, registryID, func(repositories []*string) error {
 		for _, repository := range repositories {
-			err := c.describeImages(aws.StringValue(repository), tagStatus, registryID, processFn, pageNumber)
+			err := c.describeImages(client, aws.StringValue(repository), tagStatus, registryID, processFn, pageNumber)
 			pageNumber++
 			if err != nil {
 				return err
@@ -197,7 +424,7 @@ func (c *ecrClient) GetImages(repositoryNames []*string, tagStatus string, regis
 	return err
 }
 
-func (c *ecrClient) describeRepositories(repositoryNames []*string, registryID string, outputFn ProcessRepositories) error {
+func (c *ecrClient) describeRepositories(client ecriface.ECRAPI, repositoryNames []*string, registryID string, outputFn ProcessRepositories) error {
 	var outErr error
 
 	input := &ecr.DescribeRepositoriesInput{}
@@ -212,7 +439,7 @@ func (c *ecrClient) describeRepositories(repositoryNames []*string, registryID s
 		input.SetRegistryId(registryID)
 	}
 
-	err := c.client.DescribeRepositoriesPages(input, func(resp *ecr.DescribeRepositoriesOutput, lastPage bool) bool {
+	err := client.DescribeRepositoriesPages(input, func(resp *ecr.DescribeRepositoriesOutput, lastPage bool) bool {
 		repositoryNames = []*string{}
 		for _, repository := range resp.Repositories {
 			repositoryNames = append(repositoryNames, repository.RepositoryName)
@@ -229,7 +456,7 @@ func (c *ecrClient) describeRepositories(repositoryNames []*string, registryID s
 	return outErr
 }
 
-func (c *ecrClient) describeImages(repositoryName string, tagStatus string, registryID string, outputFn ProcessImageDetails, numOfCalls int) error {
+func (c *ecrClient) describeImages(client ecriface.ECRAPI, repositoryName string, tagStatus string, registryID string, outputFn ProcessImageDetails, numOfCalls int) error {
 	var outErr error
 
 	filter := &ecr.DescribeImagesFilter{}
@@ -246,7 +473,7 @@ func (c *ecrClient) describeImages(repositoryName string, tagStatus string, regi
 		input.SetRegistryId(registryID)
 	}
 
-	err := c.client.DescribeImagesPages(input, func(resp *ecr.DescribeImagesOutput, lastPage bool) bool {
+	err := client.DescribeImagesPages(input, func(resp *ecr.DescribeImagesOutput, lastPage bool) bool {
 		if outErr = outputFn(resp.ImageDetails); outErr != nil {
 			return false
 		}
@@ -262,3 +489,98 @@ func (c *ecrClient) describeImages(repositoryName string, tagStatus string, regi
 	}
 	return outErr
 }
+
+// ErrImageScanInProgress is returned by GetImageScanFindings when a scan was just started and has not completed yet.
+var ErrImageScanInProgress = errors.New("image scan in progress, check again shortly")
+
+// ScanFindings summarizes the vulnerability scan results for a single image.
+type ScanFindings struct {
+	Status string
+	// SeverityCounts maps a finding severity (e.g. "HIGH") to its count.
+	SeverityCounts map[string]int64
+	Findings       []*ecr.ImageScanFinding
+}
+
+// severityRank orders ECR scan finding severities from least to most severe.
+var severityRank = map[string]int{
+	"UNDEFINED":     0,
+	"INFORMATIONAL": 1,
+	"LOW":           2,
+	"MEDIUM":        3,
+	"HIGH":          4,
+	"CRITICAL":      5,
+}
+
+// ExceedsSeverityThreshold reports whether f contains any findings at or above threshold (e.g. "HIGH").
+func (f *ScanFindings) ExceedsSeverityThreshold(threshold string) (bool, error) {
+	thresholdRank, ok := severityRank[strings.ToUpper(threshold)]
+	if !ok {
+		return false, errors.Errorf("unrecognized severity threshold %q", threshold)
+	}
+
+	for severity, count := range f.SeverityCounts {
+		if count == 0 {
+			continue
+		}
+		if rank, ok := severityRank[strings.ToUpper(severity)]; ok && rank >= thresholdRank {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *ecrClient) GetImageScanFindings(repositoryName, imageTagOrDigest string) (*ScanFindings, error) {
+	region := ""
+	if _, parsedRegion, parsedName, ok := parseRepositoryURI(repositoryName); ok {
+		region = parsedRegion
+		repositoryName = parsedName
+	}
+	client := c.clientForRegion(region)
+
+	imageID := &ecr.ImageIdentifier{}
+	if strings.HasPrefix(imageTagOrDigest, "sha256:") {
+		imageID.ImageDigest = aws.String(imageTagOrDigest)
+	} else {
+		imageID.ImageTag = aws.String(imageTagOrDigest)
+	}
+
+	log.WithFields(log.Fields{
+		"repository": repositoryName,
+		"image":      imageTagOrDigest,
+	}).Debug("Getting image scan findings")
+
+	resp, err := client.DescribeImageScanFindings(&ecr.DescribeImageScanFindingsInput{
+		RepositoryName: aws.String(repositoryName),
+		ImageId:        imageID,
+	})
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == ecr.ErrCodeScanNotFoundException {
+			log.WithFields(log.Fields{
+				"repository": repositoryName,
+				"image":      imageTagOrDigest,
+			}).Info("No existing scan found; starting image scan")
+
+			if _, startErr := client.StartImageScan(&ecr.StartImageScanInput{
+				RepositoryName: aws.String(repositoryName),
+				ImageId:        imageID,
+			}); startErr != nil {
+				return nil, errors.Wrap(startErr, "unable to start image scan")
+			}
+			return nil, ErrImageScanInProgress
+		}
+		return nil, errors.Wrap(err, "unable to describe image scan findings")
+	}
+
+	findings := &ScanFindings{SeverityCounts: map[string]int64{}}
+	if resp.ImageScanStatus != nil {
+		findings.Status = aws.StringValue(resp.ImageScanStatus.Status)
+	}
+	if resp.ImageScanFindings != nil {
+		for severity, count := range resp.ImageScanFindings.FindingSeverityCounts {
+			findings.SeverityCounts[severity] = aws.Int64Value(count)
+		}
+		findings.Findings = resp.ImageScanFindings.Findings
+	}
+
+	return findings, nil
+}
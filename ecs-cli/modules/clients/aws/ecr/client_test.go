@@ -0,0 +1,220 @@
+// Copyright 2015-2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecr
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRepositoryURI(t *testing.T) {
+	cases := []struct {
+		name               string
+		uri                string
+		wantRegistryID     string
+		wantRegion         string
+		wantRepositoryName string
+		wantOK             bool
+	}{
+		{
+			name:               "full URI",
+			uri:                "123456789012.dkr.ecr.us-west-2.amazonaws.com/myrepo",
+			wantRegistryID:     "123456789012",
+			wantRegion:         "us-west-2",
+			wantRepositoryName: "myrepo",
+			wantOK:             true,
+		},
+		{
+			name:               "full URI with nested repository name",
+			uri:                "123456789012.dkr.ecr.us-west-2.amazonaws.com/team/myrepo",
+			wantRegistryID:     "123456789012",
+			wantRegion:         "us-west-2",
+			wantRepositoryName: "team/myrepo",
+			wantOK:             true,
+		},
+		{
+			name:               "china partition",
+			uri:                "123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn/myrepo",
+			wantRegistryID:     "123456789012",
+			wantRegion:         "cn-north-1",
+			wantRepositoryName: "myrepo",
+			wantOK:             true,
+		},
+		{
+			name:   "bare repository name",
+			uri:    "myrepo",
+			wantOK: false,
+		},
+		{
+			name:   "registry URI with no repository path",
+			uri:    "123456789012.dkr.ecr.us-west-2.amazonaws.com",
+			wantOK: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			registryID, region, repositoryName, ok := parseRepositoryURI(c.uri)
+			assert.Equal(t, c.wantOK, ok)
+			if c.wantOK {
+				assert.Equal(t, c.wantRegistryID, registryID)
+				assert.Equal(t, c.wantRegion, region)
+				assert.Equal(t, c.wantRepositoryName, repositoryName)
+			}
+		})
+	}
+}
+
+func TestParseRegistryURI(t *testing.T) {
+	cases := []struct {
+		name           string
+		uri            string
+		wantRegistryID string
+		wantRegion     string
+		wantOK         bool
+	}{
+		{
+			name:           "bare registry URI",
+			uri:            "123456789012.dkr.ecr.us-west-2.amazonaws.com",
+			wantRegistryID: "123456789012",
+			wantRegion:     "us-west-2",
+			wantOK:         true,
+		},
+		{
+			name:   "plain registry ID",
+			uri:    "123456789012",
+			wantOK: false,
+		},
+		{
+			name:   "URI with a repository path",
+			uri:    "123456789012.dkr.ecr.us-west-2.amazonaws.com/myrepo",
+			wantOK: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			registryID, region, ok := parseRegistryURI(c.uri)
+			assert.Equal(t, c.wantOK, ok)
+			if c.wantOK {
+				assert.Equal(t, c.wantRegistryID, registryID)
+				assert.Equal(t, c.wantRegion, region)
+			}
+		})
+	}
+}
+
+func TestExceedsSeverityThreshold(t *testing.T) {
+	cases := []struct {
+		name           string
+		severityCounts map[string]int64
+		threshold      string
+		wantExceeds    bool
+		wantErr        bool
+	}{
+		{
+			name:           "finding at threshold",
+			severityCounts: map[string]int64{"HIGH": 1},
+			threshold:      "HIGH",
+			wantExceeds:    true,
+		},
+		{
+			name:           "finding above threshold",
+			severityCounts: map[string]int64{"CRITICAL": 1},
+			threshold:      "HIGH",
+			wantExceeds:    true,
+		},
+		{
+			name:           "finding below threshold",
+			severityCounts: map[string]int64{"LOW": 3},
+			threshold:      "HIGH",
+			wantExceeds:    false,
+		},
+		{
+			name:           "only UNDEFINED findings at a low threshold",
+			severityCounts: map[string]int64{"UNDEFINED": 2},
+			threshold:      "UNDEFINED",
+			wantExceeds:    true,
+		},
+		{
+			name:           "only UNDEFINED findings at a high threshold",
+			severityCounts: map[string]int64{"UNDEFINED": 2},
+			threshold:      "HIGH",
+			wantExceeds:    false,
+		},
+		{
+			name:           "zero counts do not count as findings",
+			severityCounts: map[string]int64{"CRITICAL": 0},
+			threshold:      "LOW",
+			wantExceeds:    false,
+		},
+		{
+			name:           "unrecognized threshold errors instead of silently passing",
+			severityCounts: map[string]int64{"CRITICAL": 1},
+			threshold:      "Hihg",
+			wantErr:        true,
+		},
+		{
+			name:           "threshold is case-insensitive",
+			severityCounts: map[string]int64{"high": 1},
+			threshold:      "high",
+			wantExceeds:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			findings := &ScanFindings{SeverityCounts: c.severityCounts}
+			exceeds, err := findings.ExceedsSeverityThreshold(c.threshold)
+			if c.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, c.wantExceeds, exceeds)
+		})
+	}
+}
+
+func TestResolveLifecyclePolicy(t *testing.T) {
+	t.Run("inline JSON document", func(t *testing.T) {
+		policy := `{"rules": [{"rulePriority": 1}]}`
+		resolved, err := resolveLifecyclePolicy(policy)
+		assert.NoError(t, err)
+		assert.Equal(t, policy, resolved)
+	})
+
+	t.Run("path to a policy file", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "lifecycle-policy")
+		assert.NoError(t, err)
+		defer os.RemoveAll(dir)
+
+		path := filepath.Join(dir, "policy.json")
+		want := `{"rules": [{"rulePriority": 1}]}`
+		assert.NoError(t, ioutil.WriteFile(path, []byte(want), 0644))
+
+		resolved, err := resolveLifecyclePolicy(path)
+		assert.NoError(t, err)
+		assert.Equal(t, want, resolved)
+	})
+
+	t.Run("missing file path errors", func(t *testing.T) {
+		_, err := resolveLifecyclePolicy("/no/such/file.json")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,211 @@
+// Copyright 2015-2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecr
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/clients"
+	"github.com/aws/amazon-ecs-cli/ecs-cli/modules/config"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/ecrpublic"
+	"github.com/aws/aws-sdk-go/service/ecrpublic/ecrpubliciface"
+	login "github.com/awslabs/amazon-ecr-credential-helper/ecr-login/api"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// publicRegistryRegion is the only region the ecr-public API is published in.
+const publicRegistryRegion = "us-east-1"
+
+// publicRepositoryURIPattern matches public.ecr.aws/<alias>/<repositoryName>.
+var publicRepositoryURIPattern = regexp.MustCompile(`^public\.ecr\.aws/[^/]+/(.+)$`)
+
+// parsePublicRepositoryURI extracts the bare repository name out of a public ECR repository URI.
+func parsePublicRepositoryURI(uri string) (repositoryName string, ok bool) {
+	uri = strings.TrimPrefix(strings.TrimPrefix(uri, "https://"), "http://")
+	matches := publicRepositoryURIPattern.FindStringSubmatch(uri)
+	if matches == nil {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// ecrPublicClient implements Client against the Amazon ECR Public Gallery (public.ecr.aws).
+type ecrPublicClient struct {
+	client      ecrpubliciface.ECRPublicAPI
+	loginClient login.Client
+}
+
+// NewPublicClient creates a new ECR client that talks to the public ECR Gallery.
+func NewPublicClient(config *config.CommandConfig) Client {
+	awsConfig := &aws.Config{Region: aws.String(publicRegistryRegion)}
+
+	client := ecrpublic.New(config.Session, awsConfig)
+	client.Handlers.Build.PushBackNamed(clients.CustomUserAgentHandler())
+
+	loginClient := login.DefaultClientFactory{}.NewClientWithOptions(login.Options{
+		Session:  config.Session,
+		Config:   awsConfig,
+		CacheDir: CacheDir,
+	})
+
+	return &ecrPublicClient{
+		client:      client,
+		loginClient: loginClient,
+	}
+}
+
+func (c *ecrPublicClient) GetAuthorizationToken(registryURI string) (*Auth, error) {
+	log.Debug("Getting authorization token for public registry...")
+
+	auth, err := c.loginClient.GetCredentials(registryURI)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize authorization token")
+	}
+	log.Debugf("Retrieved authorization token via endpoint: %+v", auth.ProxyEndpoint)
+
+	return &Auth{
+		Username:      auth.Username,
+		Password:      auth.Password,
+		ProxyEndpoint: auth.ProxyEndpoint,
+		Registry:      strings.Replace(auth.ProxyEndpoint, "https://", "", -1),
+	}, nil
+}
+
+func (c *ecrPublicClient) GetAuthorizationTokenByID(registryID string) (*Auth, error) {
+	log.Debug("Getting authorization token for public registry...")
+
+	auth, err := c.loginClient.GetCredentialsByRegistryID(registryID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to serialize authorization token")
+	}
+	log.Debugf("Retrieved authorization token via endpoint: %+v", auth.ProxyEndpoint)
+
+	return &Auth{
+		Username:      auth.Username,
+		Password:      auth.Password,
+		ProxyEndpoint: auth.ProxyEndpoint,
+		Registry:      strings.Replace(auth.ProxyEndpoint, "https://", "", -1),
+	}, nil
+}
+
+func (c *ecrPublicClient) RepositoryExists(repositoryName string) bool {
+	if parsedName, ok := parsePublicRepositoryURI(repositoryName); ok {
+		repositoryName = parsedName
+	}
+
+	_, err := c.client.DescribeRepositories(&ecrpublic.DescribeRepositoriesInput{RepositoryNames: []*string{&repositoryName}})
+	log.WithFields(log.Fields{
+		"repository": repositoryName,
+	}).Debug("Check if public repository exists")
+	return err == nil
+}
+
+func (c *ecrPublicClient) CreateRepository(repositoryName string, options RepositoryOptions) (string, error) {
+	if parsedName, ok := parsePublicRepositoryURI(repositoryName); ok {
+		repositoryName = parsedName
+	}
+
+	if options.LifecyclePolicy != "" || options.ImageTagMutability != "" || options.ScanOnPush {
+		log.Warn("Lifecycle policy, image tag mutability, and scan-on-push are not supported for public repositories; ignoring")
+	}
+
+	log.WithFields(log.Fields{
+		"repository": repositoryName,
+	}).Info("Creating public repository")
+
+	resp, err := c.client.CreateRepository(
+		&ecrpublic.CreateRepositoryInput{RepositoryName: aws.String(repositoryName)})
+	if err != nil {
+		return "", errors.Wrap(err, "unable to create public repository")
+	}
+	if resp == nil || resp.Repository == nil {
+		return "", errors.New("create repository response is empty")
+	}
+
+	log.Info("Public repository created")
+	return aws.StringValue(resp.Repository.RepositoryName), nil
+}
+
+func (c *ecrPublicClient) GetImageScanFindings(repositoryName, imageTagOrDigest string) (*ScanFindings, error) {
+	return nil, errors.New("image vulnerability scanning is not supported for public repositories")
+}
+
+func (c *ecrPublicClient) GetImages(repositoryNames []*string, tagStatus string, registryID string, processFn ProcessImageDetails) error {
+	log.Debug("Getting images from public ECR...")
+
+	if tagStatus != "" {
+		log.Warn("Filtering by tag status is not supported for public repositories; ignoring")
+	}
+
+	var outErr error
+	if len(repositoryNames) == 0 {
+		input := &ecrpublic.DescribeRepositoriesInput{}
+		if registryID != "" {
+			input.SetRegistryId(registryID)
+		}
+
+		err := c.client.DescribeRepositoriesPages(input, func(resp *ecrpublic.DescribeRepositoriesOutput, lastPage bool) bool {
+			repositoryNames = []*string{}
+			for _, repository := range resp.Repositories {
+				repositoryNames = append(repositoryNames, repository.RepositoryName)
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, repositoryName := range repositoryNames {
+		input := &ecrpublic.DescribeImagesInput{RepositoryName: repositoryName}
+		if registryID != "" {
+			input.SetRegistryId(registryID)
+		}
+
+		err := c.client.DescribeImagesPages(input, func(resp *ecrpublic.DescribeImagesOutput, lastPage bool) bool {
+			if outErr = processFn(toImageDetails(resp.ImageDetails)); outErr != nil {
+				return false
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return err
+		}
+		if outErr != nil {
+			return outErr
+		}
+	}
+
+	return nil
+}
+
+// toImageDetails adapts public ECR image details to the shared ProcessImageDetails callback shape.
+func toImageDetails(publicDetails []*ecrpublic.ImageDetail) []*ecr.ImageDetail {
+	details := make([]*ecr.ImageDetail, 0, len(publicDetails))
+	for _, d := range publicDetails {
+		details = append(details, &ecr.ImageDetail{
+			RegistryId:       d.RegistryId,
+			RepositoryName:   d.RepositoryName,
+			ImageDigest:      d.ImageDigest,
+			ImageTags:        d.ImageTags,
+			ImageSizeInBytes: d.ImageSizeInBytes,
+			ImagePushedAt:    d.ImagePushedAt,
+		})
+	}
+	return details
+}
@@ -0,0 +1,111 @@
+// Copyright 2015-2017 Amazon.com, Inc. or its affiliates. All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may
+// not use this file except in compliance with the License. A copy of the
+// License is located at
+//
+//     http://aws.amazon.com/apache2.0/
+//
+// or in the "license" file accompanying this file. This file is distributed
+// on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ecr
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecrpublic"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicRegistryURI(t *testing.T) {
+	cases := []struct {
+		name string
+		uri  string
+		want bool
+	}{
+		{name: "public registry with repository", uri: "public.ecr.aws/myalias/myrepo", want: true},
+		{name: "public registry with scheme", uri: "https://public.ecr.aws/myalias/myrepo", want: true},
+		{name: "bare public registry host", uri: "public.ecr.aws", want: true},
+		{name: "private registry", uri: "123456789012.dkr.ecr.us-west-2.amazonaws.com/myrepo", want: false},
+		{name: "repository name merely containing the public hostname", uri: "public.ecr.aws.evil.example.com/myrepo", want: false},
+		{name: "repository name with public.ecr.aws as a path segment", uri: "123456789012.dkr.ecr.us-west-2.amazonaws.com/public.ecr.aws", want: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, IsPublicRegistryURI(c.uri))
+		})
+	}
+}
+
+func TestParsePublicRepositoryURI(t *testing.T) {
+	cases := []struct {
+		name               string
+		uri                string
+		wantRepositoryName string
+		wantOK             bool
+	}{
+		{
+			name:               "public repository URI",
+			uri:                "public.ecr.aws/myalias/myrepo",
+			wantRepositoryName: "myrepo",
+			wantOK:             true,
+		},
+		{
+			name:               "public repository URI with a nested repository name",
+			uri:                "public.ecr.aws/myalias/team/myrepo",
+			wantRepositoryName: "team/myrepo",
+			wantOK:             true,
+		},
+		{
+			name:               "public repository URI with scheme",
+			uri:                "https://public.ecr.aws/myalias/myrepo",
+			wantRepositoryName: "myrepo",
+			wantOK:             true,
+		},
+		{
+			name:   "bare repository name",
+			uri:    "myrepo",
+			wantOK: false,
+		},
+		{
+			name:   "public registry URI with no repository path",
+			uri:    "public.ecr.aws/myalias",
+			wantOK: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			repositoryName, ok := parsePublicRepositoryURI(c.uri)
+			assert.Equal(t, c.wantOK, ok)
+			if c.wantOK {
+				assert.Equal(t, c.wantRepositoryName, repositoryName)
+			}
+		})
+	}
+}
+
+func TestToImageDetails(t *testing.T) {
+	publicDetails := []*ecrpublic.ImageDetail{
+		{
+			RegistryId:       aws.String("123456789012"),
+			RepositoryName:   aws.String("myrepo"),
+			ImageDigest:      aws.String("sha256:abc"),
+			ImageTags:        []*string{aws.String("latest")},
+			ImageSizeInBytes: aws.Int64(42),
+		},
+	}
+
+	details := toImageDetails(publicDetails)
+
+	assert.Len(t, details, 1)
+	assert.Equal(t, "123456789012", aws.StringValue(details[0].RegistryId))
+	assert.Equal(t, "myrepo", aws.StringValue(details[0].RepositoryName))
+	assert.Equal(t, "sha256:abc", aws.StringValue(details[0].ImageDigest))
+	assert.Equal(t, int64(42), aws.Int64Value(details[0].ImageSizeInBytes))
+}